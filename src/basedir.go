@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"hash"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/SAJAD-net/Dedupe/src/dedupe"
+)
+
+// baseDirOptions holds the flags for the --basedir/--dupdir replace mode.
+type baseDirOptions struct {
+	baseDir string
+	dupDir  string
+	action  string
+	minSize int64
+	chmod   string
+	fsync   bool
+	dryRun  bool
+	confirm bool
+	verbose bool
+	hashNew func() hash.Hash
+}
+
+// runBaseDupMode walks dupDir looking for files that duplicate something in
+// baseDir, then applies the requested action (print/symlink/hardlink/
+// reflink/delete) to each duplicate found.
+func runBaseDupMode(opts baseDirOptions) {
+	action, err := dedupe.ParseAction(opts.action)
+	if err != nil {
+		log.Fatalf("--action: %v", err)
+	}
+
+	var chmod os.FileMode
+	if opts.chmod != "" {
+		mode, err := strconv.ParseUint(opts.chmod, 8, 32)
+		if err != nil {
+			log.Fatalf("--chmod: %v", err)
+		}
+		chmod = os.FileMode(mode)
+	}
+
+	baseSizes := make(map[int64][]string)
+	if err := walkSizes(opts.baseDir, opts.minSize, baseSizes); err != nil {
+		log.Fatalf("Error walking --basedir %s: %v", opts.baseDir, err)
+	}
+
+	pipeline := dedupe.NewPipeline(&dedupe.Hasher{New: opts.hashNew})
+	replaceOpts := dedupe.ReplaceOptions{Action: action, Chmod: chmod, Fsync: opts.fsync}
+
+	var dupCount int
+	var totalSaved int64
+
+	err = filepath.Walk(opts.dupDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			log.Printf("Error accessing %s: %v", path, err)
+			return nil
+		}
+		if info.IsDir() || info.Mode()&os.ModeSymlink != 0 {
+			return nil
+		}
+		size := info.Size()
+		if size == 0 || size < opts.minSize {
+			return nil
+		}
+
+		basePaths := baseSizes[size]
+		if len(basePaths) == 0 {
+			return nil
+		}
+
+		original, ok, err := pipeline.FindOriginal(size, basePaths, path)
+		if err != nil {
+			log.Printf("Error hashing %s: %v", path, err)
+			return nil
+		}
+		if !ok {
+			return nil
+		}
+
+		dupCount++
+		totalSaved += size
+		fmt.Printf("%s duplicates %s\n", path, original)
+
+		if opts.dryRun || action == dedupe.ActionPrint {
+			return nil
+		}
+
+		if opts.confirm {
+			fmt.Printf("Replace %s with a %s of %s? (y/N) ", path, opts.action, original)
+			var response string
+			fmt.Scanln(&response)
+			if response != "y" && response != "Y" {
+				return nil
+			}
+		}
+
+		if err := dedupe.Replace(original, path, replaceOpts); err != nil {
+			log.Printf("Error replacing %s: %v", path, err)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error walking --dupdir %s: %v", opts.dupDir, err)
+	}
+
+	fmt.Printf("\nSummary:\n")
+	fmt.Printf("\tDuplicates found: %d\n", dupCount)
+	fmt.Printf("\tPotential space saved: %d MB\n", totalSaved/(1024*1024))
+	if opts.dryRun {
+		fmt.Println("\n\t**RUN IN DRY-RUN MODE - NO FILES WERE CHANGED**")
+	}
+}
+
+// walkSizes walks dir, appending every file of at least minSize to sizes
+// keyed by file size.
+func walkSizes(dir string, minSize int64, sizes map[int64][]string) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			log.Printf("Error accessing %s: %v", path, err)
+			return nil
+		}
+		if info.IsDir() || info.Mode()&os.ModeSymlink != 0 {
+			return nil
+		}
+		size := info.Size()
+		if size == 0 || size < minSize {
+			return nil
+		}
+		sizes[size] = append(sizes[size], path)
+		return nil
+	})
+}
@@ -1,15 +1,20 @@
 package main
 
 import (
-	"crypto/sha256"
+	"context"
 	"flag"
 	"fmt"
-	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sort"
 	"time"
+
+	"github.com/SAJAD-net/Dedupe/src/dedupe"
+	"github.com/SAJAD-net/Dedupe/src/hashalgo"
+	"github.com/SAJAD-net/Dedupe/src/report"
+	"github.com/SAJAD-net/Dedupe/src/scanner"
 )
 
 func main() {
@@ -18,43 +23,137 @@ func main() {
 	dryRun := flag.Bool("dry-run", false, "Show what would be deleted without actually deleting")
 	confirm := flag.Bool("confirm", false, "Require confirmation before deletion")
 	verbose := flag.Bool("verbose", false, "Show detailed progress")
+
+	baseDir := flag.String("basedir", "", "Directory holding the canonical copies; duplicates found in --dupdir are linked back here")
+	dupDir := flag.String("dupdir", "", "Directory to scan for files that duplicate something in --basedir")
+	action := flag.String("action", "print", "What to do with a duplicate found in --dupdir: print|symlink|hardlink|reflink|delete")
+	minSize := flag.Int64("minsize", 0, "Ignore files smaller than this many bytes (basedir/dupdir mode)")
+	chmodFlag := flag.String("chmod", "", "Octal file mode applied to a replaced duplicate, e.g. 0444")
+	fsyncFlag := flag.Bool("fsync", false, "Fsync the parent directory around each replace, for crash safety")
+
+	jobs := flag.Int("jobs", runtime.NumCPU(), "Number of size-collision groups to hash concurrently")
+	ioLimit := flag.Int64("io-limit", 0, "Throttle hashing reads to this many bytes/sec (0 = unlimited)")
+	keepHardlinks := flag.Bool("keep-hardlinks", false, "Treat hardlinked siblings as one group and never delete the last remaining link")
+
+	hashName := flag.String("hash", hashalgo.Default, fmt.Sprintf("Digest algorithm to use: %v", hashalgo.Names()))
+	verify := flag.Bool("verify", false, "Byte-for-byte compare a duplicate against its keeper before deleting it")
+
+	reportFormat := flag.String("report", "", "Emit a structured report instead of the human-readable output: json|netstring|csv")
+	reportFile := flag.String("report-file", "", "Where to write --report output; defaults to stdout")
+
+	fromStdin := flag.Bool("from-stdin", false, "Read the candidate file list from stdin instead of walking --partitions")
+	nullDelimited := flag.Bool("null", false, "With --from-stdin, paths are NUL-delimited (for find -print0) instead of newline-delimited")
+	var include, exclude stringList
+	flag.Var(&include, "include", "Only consider files whose base name matches this glob (repeatable)")
+	flag.Var(&exclude, "exclude", "Skip files whose base name matches this glob (repeatable)")
+	minSizeFilter := flag.Int64("min-size", 0, "Skip files smaller than this many bytes")
+	maxSizeFilter := flag.Int64("max-size", 0, "Skip files larger than this many bytes (0 = unlimited)")
+	newerThan := flag.String("newer-than", "", "Skip files last modified before this RFC3339 timestamp")
+	olderThan := flag.String("older-than", "", "Skip files last modified after this RFC3339 timestamp")
 	flag.Parse()
 
-	if *partitions == "" {
-		log.Fatal("Please specify partitions/directories to scan using --partitions")
+	var newerThanTime, olderThanTime time.Time
+	var err error
+	if *newerThan != "" {
+		newerThanTime, err = time.Parse(time.RFC3339, *newerThan)
+		if err != nil {
+			log.Fatalf("--newer-than: %v", err)
+		}
+	}
+	if *olderThan != "" {
+		olderThanTime, err = time.Parse(time.RFC3339, *olderThan)
+		if err != nil {
+			log.Fatalf("--older-than: %v", err)
+		}
+	}
+
+	hashNew, err := hashalgo.New(*hashName)
+	if err != nil {
+		log.Fatalf("--hash: %v", err)
 	}
 
-	// Collect all files with their sizes
+	var reporter report.Reporter
+	if *reportFormat != "" {
+		w := os.Stdout
+		if *reportFile != "" {
+			f, err := os.Create(*reportFile)
+			if err != nil {
+				log.Fatalf("--report-file: %v", err)
+			}
+			defer f.Close()
+			w = f
+		}
+		reporter, err = report.New(*reportFormat, w)
+		if err != nil {
+			log.Fatalf("--report: %v", err)
+		}
+		defer reporter.Close()
+	}
+
+	if *baseDir != "" || *dupDir != "" {
+		if *baseDir == "" || *dupDir == "" {
+			log.Fatal("--basedir and --dupdir must both be set")
+		}
+		runBaseDupMode(baseDirOptions{
+			baseDir: *baseDir,
+			dupDir:  *dupDir,
+			action:  *action,
+			minSize: *minSize,
+			chmod:   *chmodFlag,
+			fsync:   *fsyncFlag,
+			dryRun:  *dryRun,
+			confirm: *confirm,
+			verbose: *verbose,
+			hashNew: hashNew,
+		})
+		return
+	}
+
+	if *partitions == "" && !*fromStdin {
+		log.Fatal("Please specify partitions/directories to scan using --partitions, or pass --from-stdin")
+	}
+
+	scanOpts := scanner.Options{
+		FromStdin:     *fromStdin,
+		NullDelimited: *nullDelimited,
+		Include:       include,
+		Exclude:       exclude,
+		MinSize:       *minSizeFilter,
+		MaxSize:       *maxSizeFilter,
+		NewerThan:     newerThanTime,
+		OlderThan:     olderThanTime,
+	}
+	if *partitions != "" {
+		scanOpts.Roots = filepath.SplitList(*partitions)
+	}
+
+	// Collect all files with their sizes, collapsing any already-hardlinked
+	// siblings down to one representative since they're the same on-disk
+	// file, not a duplicate.
 	sizeMap := make(map[int64][]string)
+	inodes := newInodeIndex()
 	startTime := time.Now()
 
-	// Walk through each partition
-	for _, partition := range filepath.SplitList(*partitions) {
-		if *verbose {
-			log.Printf("Scanning partition: %s", partition)
+	if *verbose {
+		for _, root := range scanOpts.Roots {
+			log.Printf("Scanning partition: %s", root)
 		}
+	}
 
-		err := filepath.Walk(partition, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				log.Printf("Error accessing %s: %v", path, err)
-				return nil
-			}
-			if info.IsDir() {
-				return nil
-			}
-			if info.Mode()&os.ModeSymlink != 0 {
-				return nil
-			}
-			size := info.Size()
-			if size == 0 {
-				return nil
-			}
-			sizeMap[size] = append(sizeMap[size], path)
-			return nil
-		})
-		if err != nil {
-			log.Printf("Error walking partition %s: %v", partition, err)
+	for entry := range scanner.Scan(context.Background(), scanOpts) {
+		if entry.Info.Size() == 0 {
+			continue
 		}
+		if !inodes.Observe(entry.Path, entry.Info) {
+			continue
+		}
+		sizeMap[entry.Info.Size()] = append(sizeMap[entry.Info.Size()], entry.Path)
+	}
+
+	// Sort each size bucket by (device, inode) so hashing visits files in
+	// an order that's kinder to a spinning disk's seek time.
+	for _, files := range sizeMap {
+		sortByInodeLocality(files)
 	}
 
 	if *verbose {
@@ -73,28 +172,14 @@ func main() {
 		log.Printf("Found %d potential duplicates by size", potentialDuplicates)
 	}
 
-	// Check actual file contents using hash (SHA256)
-	hashMap := make(map[string][]string)
+	// Check actual file contents by running the staged head/tail -> partial
+	// -> full hashing pipeline over each size-collision group, hashing
+	// groups concurrently across a worker pool.
+	pipeline := dedupe.NewPipeline(&dedupe.Hasher{New: hashNew})
 	totalFiles := 0
-
-	for size, files := range sizeMap {
-		if len(files) < 2 {
-			continue
-		}
-
-		if *verbose {
-			log.Printf("Processing %d files of size %d bytes", len(files), size)
-		}
-
-		for _, path := range files {
-			hash, err := fileHash(path)
-			if err != nil {
-				log.Printf("Error hashing %s: %v", path, err)
-				continue
-			}
-
-			hashMap[hash] = append(hashMap[hash], path)
-			totalFiles++
+	for _, files := range sizeMap {
+		if len(files) > 1 {
+			totalFiles += len(files)
 		}
 	}
 
@@ -102,10 +187,8 @@ func main() {
 	duplicateCount := 0
 	totalSaved := int64(0)
 
-	for hash, files := range hashMap {
-		if len(files) < 2 {
-			continue
-		}
+	for group := range pipeline.RunAllStream(sizeMap, dedupe.PoolOptions{Jobs: *jobs, IOLimit: *ioLimit}) {
+		files := group.Paths
 
 		// Sort files by partition and modification time (oldest first)
 		sort.Slice(files, func(i, j int) bool {
@@ -125,14 +208,28 @@ func main() {
 		keeper := files[0]
 		duplicates := files[1:]
 
-		fmt.Printf("\nDuplicate group (%s):\n", hash[:8])
-		fmt.Printf("  Keeper: %s\n", keeper)
+		if reporter == nil {
+			fmt.Printf("\nDuplicate group (%d bytes, %d files):\n", group.Size, len(files))
+			fmt.Printf("  Keeper: %s\n", keeper)
+		}
+
+		rec := report.Record{Hash: group.Hash, Algorithm: *hashName, Size: group.Size, Keeper: keeper}
+		if keeperInfo, err := os.Stat(keeper); err == nil {
+			rec.KeeperMTime = keeperInfo.ModTime()
+		}
+
 		for _, dup := range duplicates {
 			duplicateCount++
 			info, _ := os.Stat(dup)
 			totalSaved += info.Size()
 
-			fmt.Printf("  Duplicate: %s\n", dup)
+			rec.Duplicates = append(rec.Duplicates, dup)
+			rec.DuplicateMTimes = append(rec.DuplicateMTimes, info.ModTime())
+			rec.BytesReclaimable += info.Size()
+
+			if reporter == nil {
+				fmt.Printf("  Duplicate: %s\n", dup)
+			}
 
 			if !*dryRun {
 				if *confirm {
@@ -144,17 +241,51 @@ func main() {
 					}
 				}
 
+				if !inodes.AllowDelete(dup, *keepHardlinks) {
+					if reporter == nil {
+						fmt.Printf("Skipping %s: last remaining hardlink (--keep-hardlinks)\n", dup)
+					}
+					continue
+				}
+
+				if *verify {
+					equal, err := dedupe.VerifyEqual(keeper, dup)
+					if err != nil {
+						log.Printf("Error verifying %s against %s: %v", dup, keeper, err)
+						continue
+					}
+					if !equal {
+						log.Printf("Refusing to delete %s: byte-for-byte verify against %s failed (hash collision?)", dup, keeper)
+						continue
+					}
+				}
+
 				err := os.Remove(dup)
 				if err != nil {
 					log.Printf("Error deleting %s: %v", dup, err)
-				} else {
+				} else if reporter == nil {
 					fmt.Printf("Deleted %s\n", dup)
 				}
 			}
 		}
+
+		if reporter != nil {
+			if err := reporter.WriteRecord(rec); err != nil {
+				log.Printf("Error writing report record: %v", err)
+			}
+		}
+	}
+
+	if err := pipeline.StreamErr(); err != nil {
+		log.Printf("Error hashing files: %v", err)
+	}
+
+	if reporter != nil {
+		return
 	}
 
 	fmt.Printf("\nSummary:\n")
+	fmt.Printf("\tHash algorithm: %s\n", *hashName)
 	fmt.Printf("\tTotal files processed: %d\n", totalFiles)
 	fmt.Printf("\tTotal duplicates found: %d\n", duplicateCount)
 	fmt.Printf("\tPotential space saved: %d MB\n", totalSaved/(1024*1024))
@@ -162,18 +293,3 @@ func main() {
 		fmt.Println("\n\t**RUN IN DRY-RUN MODE - NO FILES WERE DELETED**")
 	}
 }
-
-// Calculate file's hash (SHA256)
-func fileHash(path string) (string, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return "", err
-	}
-	defer file.Close()
-
-	hash := sha256.New()
-	if _, err := io.Copy(hash, file); err != nil {
-		return "", err
-	}
-	return fmt.Sprintf("%x", hash.Sum(nil)), nil
-}
@@ -0,0 +1,143 @@
+package dedupe
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/SAJAD-net/Dedupe/src/reflink"
+)
+
+// Action describes what to do with a duplicate once its original has been
+// identified.
+type Action int
+
+const (
+	// ActionPrint only reports the duplicate; nothing on disk changes.
+	ActionPrint Action = iota
+	// ActionSymlink replaces the duplicate with a symlink to the original.
+	ActionSymlink
+	// ActionHardlink replaces the duplicate with a hardlink to the original.
+	ActionHardlink
+	// ActionReflink replaces the duplicate with a copy-on-write clone of
+	// the original, falling back to a hardlink where unsupported.
+	ActionReflink
+	// ActionDelete removes the duplicate outright.
+	ActionDelete
+)
+
+// ParseAction converts a --action flag value into an Action.
+func ParseAction(s string) (Action, error) {
+	switch s {
+	case "print":
+		return ActionPrint, nil
+	case "symlink":
+		return ActionSymlink, nil
+	case "hardlink":
+		return ActionHardlink, nil
+	case "reflink":
+		return ActionReflink, nil
+	case "delete":
+		return ActionDelete, nil
+	default:
+		return ActionPrint, fmt.Errorf("unknown action %q", s)
+	}
+}
+
+// ReplaceOptions controls how Replace links a duplicate back to its
+// original.
+type ReplaceOptions struct {
+	Action Action
+	// Chmod, if non-zero, is applied to the replaced duplicate.
+	Chmod os.FileMode
+	// Fsync, when true, fsyncs the parent directory before the replacement
+	// is renamed over the duplicate and again after the rename, so a crash can never leave
+	// the duplicate missing with no replacement in place.
+	Fsync bool
+}
+
+// Replace links dup back to original according to opts, crash-safely: the
+// new entry is built at a temp name beside dup, the directory is fsynced,
+// then the temp name is renamed over dup and the directory is fsynced
+// again. dup is never unlinked until its replacement already exists on
+// disk.
+func Replace(original, dup string, opts ReplaceOptions) error {
+	if opts.Action == ActionPrint {
+		return nil
+	}
+
+	dir := filepath.Dir(dup)
+	tmp := filepath.Join(dir, fmt.Sprintf(".%s.dedupe-tmp", filepath.Base(dup)))
+	os.Remove(tmp)
+
+	switch opts.Action {
+	case ActionDelete:
+		return os.Remove(dup)
+
+	case ActionSymlink:
+		// Symlink targets are resolved relative to the symlink's own
+		// directory, not the process's cwd, so original must be made
+		// absolute before it's used as one.
+		target, err := filepath.Abs(original)
+		if err != nil {
+			return err
+		}
+		if err := os.Symlink(target, tmp); err != nil {
+			return err
+		}
+
+	case ActionHardlink:
+		if err := os.Link(original, tmp); err != nil {
+			return err
+		}
+
+	case ActionReflink:
+		if err := reflink.Clone(original, tmp); err != nil {
+			if err != reflink.ErrNotSupported {
+				return err
+			}
+			if err := os.Link(original, tmp); err != nil {
+				return err
+			}
+		}
+
+	default:
+		return fmt.Errorf("dedupe: unsupported action %v", opts.Action)
+	}
+
+	if opts.Chmod != 0 {
+		if err := os.Chmod(tmp, opts.Chmod); err != nil {
+			os.Remove(tmp)
+			return err
+		}
+	}
+
+	if opts.Fsync {
+		if err := fsyncDir(dir); err != nil {
+			os.Remove(tmp)
+			return err
+		}
+	}
+
+	if err := os.Rename(tmp, dup); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	if opts.Fsync {
+		if err := fsyncDir(dir); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func fsyncDir(dir string) error {
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return f.Sync()
+}
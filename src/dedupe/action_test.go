@@ -0,0 +1,98 @@
+package dedupe
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestReplaceReflinkFallsBackToHardlink exercises ActionReflink on a
+// filesystem that doesn't support FICLONE (e.g. tmpfs, as used by
+// t.TempDir() in most CI sandboxes): Replace should fall back to a
+// hardlink rather than failing the whole operation.
+func TestReplaceReflinkFallsBackToHardlink(t *testing.T) {
+	dir := t.TempDir()
+	original := filepath.Join(dir, "original")
+	dup := filepath.Join(dir, "dup")
+
+	if err := os.WriteFile(original, []byte("same content"), 0o644); err != nil {
+		t.Fatalf("WriteFile(original): %v", err)
+	}
+	if err := os.WriteFile(dup, []byte("stale content"), 0o644); err != nil {
+		t.Fatalf("WriteFile(dup): %v", err)
+	}
+
+	if err := Replace(original, dup, ReplaceOptions{Action: ActionReflink}); err != nil {
+		t.Fatalf("Replace: %v", err)
+	}
+
+	origInfo, err := os.Stat(original)
+	if err != nil {
+		t.Fatalf("Stat(original): %v", err)
+	}
+	dupInfo, err := os.Stat(dup)
+	if err != nil {
+		t.Fatalf("Stat(dup): %v", err)
+	}
+	if !os.SameFile(origInfo, dupInfo) {
+		t.Fatal("dup is not linked to original after reflink fallback")
+	}
+
+	got, err := os.ReadFile(dup)
+	if err != nil {
+		t.Fatalf("ReadFile(dup): %v", err)
+	}
+	if string(got) != "same content" {
+		t.Fatalf("dup content = %q, want %q", got, "same content")
+	}
+}
+
+func TestReplaceSymlinkAbsoluteTarget(t *testing.T) {
+	dir := t.TempDir()
+	subA := filepath.Join(dir, "a")
+	subB := filepath.Join(dir, "b")
+	if err := os.Mkdir(subA, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(subB, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	original := filepath.Join(subA, "original")
+	dup := filepath.Join(subB, "dup")
+	if err := os.WriteFile(original, []byte("content"), 0o644); err != nil {
+		t.Fatalf("WriteFile(original): %v", err)
+	}
+	if err := os.WriteFile(dup, []byte("stale"), 0o644); err != nil {
+		t.Fatalf("WriteFile(dup): %v", err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	relOriginal, err := filepath.Rel(wd, original)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Replace(relOriginal, dup, ReplaceOptions{Action: ActionSymlink}); err != nil {
+		t.Fatalf("Replace: %v", err)
+	}
+
+	target, err := os.Readlink(dup)
+	if err != nil {
+		t.Fatalf("Readlink: %v", err)
+	}
+	if !filepath.IsAbs(target) {
+		t.Fatalf("symlink target %q is not absolute", target)
+	}
+
+	got, err := os.ReadFile(dup)
+	if err != nil {
+		t.Fatalf("ReadFile(dup) through symlink: %v", err)
+	}
+	if string(got) != "content" {
+		t.Fatalf("dup content through symlink = %q, want %q", got, "content")
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package dedupe
+
+import "os"
+
+// deviceID is unavailable on this platform, so every file is treated as
+// living on the same device.
+func deviceID(fi os.FileInfo) (dev uint64, ok bool) {
+	return 0, false
+}
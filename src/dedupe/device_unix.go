@@ -0,0 +1,17 @@
+//go:build unix
+
+package dedupe
+
+import (
+	"os"
+
+	"github.com/SAJAD-net/Dedupe/src/osutil"
+)
+
+// deviceID returns the device number backing fi, used to key the
+// per-device worker pool so concurrent hashing doesn't thrash a single
+// spinning disk. ok is false if the platform can't report one.
+func deviceID(fi os.FileInfo) (dev uint64, ok bool) {
+	dev, _, ok = osutil.GetDevIno(fi)
+	return dev, ok
+}
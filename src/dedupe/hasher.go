@@ -0,0 +1,108 @@
+// Package dedupe implements the staged comparison pipeline used to turn a
+// set of same-size files into groups of confirmed duplicates while reading
+// as little of each file as possible.
+package dedupe
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+)
+
+// headTailSize is the number of bytes sampled from the start and end of a
+// file during the HeadTail stage.
+const headTailSize = 128
+
+// Hasher computes progressively more expensive digests of a file so that
+// callers can bail out as soon as two files are known to differ.
+type Hasher struct {
+	// New builds the underlying hash.Hash used for HeadTail, Partial and
+	// Full digests. Defaults to sha256.New when nil.
+	New func() hash.Hash
+
+	// Limiter, if set, caps how fast Partial and Full may read from disk.
+	Limiter *Limiter
+}
+
+func (h *Hasher) newHash() hash.Hash {
+	if h.New != nil {
+		return h.New()
+	}
+	return sha256.New()
+}
+
+// HeadTail returns a cheap fingerprint made of the first and last
+// headTailSize bytes of the file. It is meant to weed out files that only
+// happen to share a size.
+func (h *Hasher) HeadTail(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	head := make([]byte, headTailSize)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	head = head[:n]
+
+	tail := make([]byte, headTailSize)
+	tailN := int64(0)
+	if info.Size() > int64(len(head)) {
+		tailN = headTailSize
+		if _, err := f.Seek(-tailN, io.SeekEnd); err != nil {
+			return "", err
+		}
+		read, err := io.ReadFull(f, tail)
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return "", err
+		}
+		tail = tail[:read]
+	} else {
+		tail = nil
+	}
+
+	sum := h.newHash()
+	sum.Write(head)
+	sum.Write(tail)
+	return fmt.Sprintf("%x", sum.Sum(nil)), nil
+}
+
+// Partial hashes at most the first n bytes of the file.
+func (h *Hasher) Partial(path string, n int64) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	sum := h.newHash()
+	if _, err := io.CopyN(sum, throttle(f, h.Limiter), n); err != nil && err != io.EOF {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sum.Sum(nil)), nil
+}
+
+// Full hashes the entire file.
+func (h *Hasher) Full(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	sum := h.newHash()
+	if _, err := io.Copy(sum, throttle(f, h.Limiter)); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sum.Sum(nil)), nil
+}
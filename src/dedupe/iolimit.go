@@ -0,0 +1,73 @@
+package dedupe
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// Limiter throttles reads to a target bytes-per-second rate using a simple
+// token bucket. A nil *Limiter imposes no limit.
+type Limiter struct {
+	mu          sync.Mutex
+	bytesPerSec float64
+	tokens      float64
+	last        time.Time
+}
+
+// NewLimiter returns a Limiter capped at bytesPerSec. A non-positive rate
+// disables throttling (NewLimiter returns nil).
+func NewLimiter(bytesPerSec int64) *Limiter {
+	if bytesPerSec <= 0 {
+		return nil
+	}
+	return &Limiter{bytesPerSec: float64(bytesPerSec), last: time.Now()}
+}
+
+// WaitN blocks until n bytes' worth of budget is available.
+func (l *Limiter) WaitN(n int) {
+	if l == nil || n <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.bytesPerSec
+	l.last = now
+	if l.tokens > l.bytesPerSec {
+		l.tokens = l.bytesPerSec
+	}
+
+	l.tokens -= float64(n)
+	if l.tokens >= 0 {
+		return
+	}
+
+	wait := time.Duration(-l.tokens / l.bytesPerSec * float64(time.Second))
+	l.tokens = 0
+	l.mu.Unlock()
+	time.Sleep(wait)
+	l.mu.Lock()
+}
+
+// limitedReader wraps an io.Reader so every Read call is metered by a
+// Limiter.
+type limitedReader struct {
+	r io.Reader
+	l *Limiter
+}
+
+func (lr *limitedReader) Read(p []byte) (int, error) {
+	n, err := lr.r.Read(p)
+	lr.l.WaitN(n)
+	return n, err
+}
+
+func throttle(r io.Reader, l *Limiter) io.Reader {
+	if l == nil {
+		return r
+	}
+	return &limitedReader{r: r, l: l}
+}
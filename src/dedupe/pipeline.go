@@ -0,0 +1,116 @@
+package dedupe
+
+import (
+	"fmt"
+	"sync"
+)
+
+// minSizeForStaging is the smallest file size for which the staged
+// head/tail -> partial -> full pipeline is worth running. Smaller files are
+// just hashed in full, since sampling them saves nothing.
+const minSizeForStaging = 64 * 1024
+
+// partialSize is the number of leading bytes read during the Partial stage.
+const partialSize = 64 * 1024
+
+// Group is a set of file paths that share the given size and, once Run
+// completes, an identical full-file hash.
+type Group struct {
+	Size  int64
+	Hash  string
+	Paths []string
+}
+
+// Pipeline drives a Hasher through the head/tail -> partial -> full stages,
+// narrowing each same-size group down to its real duplicate subsets and
+// short-circuiting as soon as a group can no longer contain a duplicate.
+type Pipeline struct {
+	Hasher *Hasher
+
+	// streamErr/streamErrMu record the first hashing error seen by Run,
+	// RunAll or RunAllStream, since a path that fails to hash is dropped
+	// rather than returned as an error.
+	streamErr   error
+	streamErrMu sync.Mutex
+}
+
+// NewPipeline returns a Pipeline backed by the given Hasher, or a default
+// SHA256 Hasher if h is nil.
+func NewPipeline(h *Hasher) *Pipeline {
+	if h == nil {
+		h = &Hasher{}
+	}
+	return &Pipeline{Hasher: h}
+}
+
+// Run reduces a single size-collision group into groups of files whose
+// full-file hashes match; the bytes themselves are not compared (see
+// VerifyEqual). Groups of size 1 are dropped.
+func (p *Pipeline) Run(size int64, paths []string) ([]Group, error) {
+	if len(paths) < 2 {
+		return nil, nil
+	}
+
+	if size < minSizeForStaging {
+		return p.stage(size, paths, p.Hasher.Full)
+	}
+
+	headTail, err := p.stage(size, paths, p.Hasher.HeadTail)
+	if err != nil {
+		return nil, err
+	}
+
+	var partialGroups []Group
+	for _, g := range headTail {
+		if len(g.Paths) < 2 {
+			continue
+		}
+		sub, err := p.stage(size, g.Paths, func(path string) (string, error) {
+			return p.Hasher.Partial(path, partialSize)
+		})
+		if err != nil {
+			return nil, err
+		}
+		partialGroups = append(partialGroups, sub...)
+	}
+
+	var fullGroups []Group
+	for _, g := range partialGroups {
+		if len(g.Paths) < 2 {
+			continue
+		}
+		sub, err := p.stage(size, g.Paths, p.Hasher.Full)
+		if err != nil {
+			return nil, err
+		}
+		fullGroups = append(fullGroups, sub...)
+	}
+
+	return fullGroups, nil
+}
+
+// stage hashes every path in paths with digest and buckets them by the
+// resulting value, discarding singleton buckets. A path that fails to hash
+// (removed mid-run, permission denied, I/O error, ...) is recorded via
+// streamErr and dropped, rather than failing the whole group: the other
+// paths may still be confirmed duplicates of each other.
+func (p *Pipeline) stage(size int64, paths []string, digest func(string) (string, error)) ([]Group, error) {
+	buckets := make(map[string][]string, len(paths))
+	for _, path := range paths {
+		sum, err := digest(path)
+		if err != nil {
+			p.recordStreamErr(fmt.Errorf("hashing %s: %w", path, err))
+			continue
+		}
+		buckets[sum] = append(buckets[sum], path)
+	}
+
+	groups := make([]Group, 0, len(buckets))
+	for sum, bucketPaths := range buckets {
+		if len(bucketPaths) < 2 {
+			continue
+		}
+		groups = append(groups, Group{Size: size, Hash: sum, Paths: bucketPaths})
+	}
+	return groups, nil
+}
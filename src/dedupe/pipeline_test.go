@@ -0,0 +1,190 @@
+package dedupe
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// writeFiles creates one file per name in t.TempDir() with the given
+// content and returns their full paths in the same order as names.
+func writeFiles(t *testing.T, names []string, content map[string][]byte) []string {
+	t.Helper()
+	dir := t.TempDir()
+	paths := make([]string, len(names))
+	for i, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, content[name], 0o644); err != nil {
+			t.Fatalf("WriteFile(%s): %v", name, err)
+		}
+		paths[i] = path
+	}
+	return paths
+}
+
+// sortedGroupNames maps each Group's paths back to base names and sorts
+// both the outer groups and each group's members, so test expectations
+// don't depend on map iteration order.
+func sortedGroupNames(groups []Group) [][]string {
+	out := make([][]string, len(groups))
+	for i, g := range groups {
+		names := make([]string, len(g.Paths))
+		for j, p := range g.Paths {
+			names[j] = filepath.Base(p)
+		}
+		sort.Strings(names)
+		out[i] = names
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if len(out[i]) != len(out[j]) {
+			return len(out[i]) < len(out[j])
+		}
+		return out[i][0] < out[j][0]
+	})
+	return out
+}
+
+func TestPipelineRun(t *testing.T) {
+	fill := func(size int, b byte) []byte {
+		buf := make([]byte, size)
+		for i := range buf {
+			buf[i] = b
+		}
+		return buf
+	}
+
+	tests := []struct {
+		name    string
+		size    int
+		content func() map[string][]byte
+		want    [][]string
+	}{
+		{
+			name: "small identical files below staging threshold are grouped",
+			size: 100,
+			content: func() map[string][]byte {
+				return map[string][]byte{"a": fill(100, 'x'), "b": fill(100, 'x')}
+			},
+			want: [][]string{{"a", "b"}},
+		},
+		{
+			name: "small differing files below staging threshold are not grouped",
+			size: 100,
+			content: func() map[string][]byte {
+				return map[string][]byte{"a": fill(100, 'x'), "b": fill(100, 'y')}
+			},
+			want: nil,
+		},
+		{
+			name: "identical files exactly at the staging threshold are grouped",
+			size: minSizeForStaging,
+			content: func() map[string][]byte {
+				return map[string][]byte{"a": fill(minSizeForStaging, 'x'), "b": fill(minSizeForStaging, 'x')}
+			},
+			want: [][]string{{"a", "b"}},
+		},
+		{
+			name: "matching head and tail but differing middle is rejected, not a false positive",
+			size: minSizeForStaging + 10000,
+			content: func() map[string][]byte {
+				size := minSizeForStaging + 10000
+				a := fill(size, 'x')
+				b := fill(size, 'x')
+				// Differs well inside the head/tail sample's blind spot
+				// (past the first/last headTailSize bytes) but still
+				// inside the partial stage's read window, so the partial
+				// stage -- not just the final full hash -- must catch it.
+				b[headTailSize+50] = 'z'
+				return map[string][]byte{"a": a, "b": b}
+			},
+			want: nil,
+		},
+		{
+			name: "full stage splits a group that only diverges beyond partialSize",
+			size: minSizeForStaging + 10000,
+			content: func() map[string][]byte {
+				size := minSizeForStaging + 10000
+				a := fill(size, 'x')
+				b := fill(size, 'x')
+				c := fill(size, 'x')
+				// c matches a and b through the head/tail sample and the
+				// entire partial-stage read window, and only diverges
+				// afterward -- so only the full-hash stage can tell it
+				// apart from the real a/b duplicate pair.
+				c[partialSize+50] = 'z'
+				return map[string][]byte{"a": a, "b": b, "c": c}
+			},
+			want: [][]string{{"a", "b"}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			content := tc.content()
+			names := make([]string, 0, len(content))
+			for name := range content {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			paths := writeFiles(t, names, content)
+
+			p := NewPipeline(nil)
+			groups, err := p.Run(int64(tc.size), paths)
+			if err != nil {
+				t.Fatalf("Run: %v", err)
+			}
+			got := sortedGroupNames(groups)
+			if len(got) != len(tc.want) {
+				t.Fatalf("Run() groups = %v, want %v", got, tc.want)
+			}
+			for i := range got {
+				if len(got[i]) != len(tc.want[i]) {
+					t.Fatalf("Run() groups = %v, want %v", got, tc.want)
+				}
+				for j := range got[i] {
+					if got[i][j] != tc.want[i][j] {
+						t.Fatalf("Run() groups = %v, want %v", got, tc.want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPipelineRunSinglePathIsNotAGroup(t *testing.T) {
+	paths := writeFiles(t, []string{"a"}, map[string][]byte{"a": []byte("content")})
+	p := NewPipeline(nil)
+	groups, err := p.Run(7, paths)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if groups != nil {
+		t.Fatalf("Run() with one path = %v, want nil", groups)
+	}
+}
+
+func TestPipelineRunSkipsUnreadablePathButKeepsOthers(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a")
+	b := filepath.Join(dir, "b")
+	missing := filepath.Join(dir, "missing")
+	if err := os.WriteFile(a, []byte("same content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, []byte("same content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewPipeline(nil)
+	groups, err := p.Run(int64(len("same content")), []string{a, b, missing})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(groups) != 1 || len(groups[0].Paths) != 2 {
+		t.Fatalf("Run() groups = %v, want one group of {a, b}", groups)
+	}
+	if p.StreamErr() == nil {
+		t.Fatal("StreamErr() = nil, want the missing path's hashing error recorded")
+	}
+}
@@ -0,0 +1,125 @@
+package dedupe
+
+import (
+	"os"
+	"runtime"
+	"sync"
+)
+
+// perDeviceConcurrency bounds how many size-collision groups on the same
+// physical device are hashed at once, so a handful of huge groups on one
+// spinning disk can't starve every other group's I/O.
+const perDeviceConcurrency = 2
+
+// PoolOptions configures RunAll's concurrency.
+type PoolOptions struct {
+	// Jobs is the overall cap on concurrently-hashed groups. Defaults to
+	// runtime.NumCPU() when <= 0.
+	Jobs int
+	// IOLimit, in bytes/sec, throttles Partial and Full reads. 0 disables
+	// throttling.
+	IOLimit int64
+}
+
+// RunAll hashes every size-collision group in sizeGroups concurrently,
+// bounded by opts.Jobs overall and by a small per-device pool so groups on
+// the same disk don't thrash each other, and returns every confirmed
+// duplicate group it finds.
+func (p *Pipeline) RunAll(sizeGroups map[int64][]string, opts PoolOptions) ([]Group, error) {
+	groups := make([]Group, 0)
+	for g := range p.RunAllStream(sizeGroups, opts) {
+		groups = append(groups, g)
+	}
+	return groups, p.streamErr
+}
+
+// RunAllStream is the streaming form of RunAll: it returns a channel that
+// yields each confirmed duplicate group as soon as its pipeline finishes,
+// rather than buffering the whole result set in memory. Any per-group error
+// is recorded in streamErr, which RunAll returns after the channel closes;
+// RunAllStream callers should check StreamErr once the channel is drained.
+func (p *Pipeline) RunAllStream(sizeGroups map[int64][]string, opts PoolOptions) <-chan Group {
+	jobs := opts.Jobs
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	if opts.IOLimit > 0 && p.Hasher.Limiter == nil {
+		p.Hasher.Limiter = NewLimiter(opts.IOLimit)
+	}
+
+	out := make(chan Group)
+	global := make(chan struct{}, jobs)
+	var deviceSems sync.Map // uint64 -> chan struct{}
+
+	var wg sync.WaitGroup
+	for size, paths := range sizeGroups {
+		if len(paths) < 2 {
+			continue
+		}
+
+		size, paths := size, paths
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			global <- struct{}{}
+			defer func() { <-global }()
+
+			devSem := deviceSemFor(&deviceSems, paths[0])
+			if devSem != nil {
+				devSem <- struct{}{}
+				defer func() { <-devSem }()
+			}
+
+			groups, err := p.Run(size, paths)
+			if err != nil {
+				p.recordStreamErr(err)
+				return
+			}
+			for _, g := range groups {
+				out <- g
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
+
+func deviceSemFor(sems *sync.Map, path string) chan struct{} {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	dev, ok := deviceID(fi)
+	if !ok {
+		return nil
+	}
+	sem, _ := sems.LoadOrStore(dev, make(chan struct{}, perDeviceConcurrency))
+	return sem.(chan struct{})
+}
+
+func (p *Pipeline) recordStreamErr(err error) {
+	p.streamErrMu.Lock()
+	defer p.streamErrMu.Unlock()
+	if p.streamErr == nil {
+		p.streamErr = err
+	}
+}
+
+// StreamErr returns the first error recorded while hashing, or nil if every
+// path hashed cleanly. RunAllStream callers should check it once the
+// channel they were given has been drained.
+func (p *Pipeline) StreamErr() error {
+	p.streamErrMu.Lock()
+	defer p.streamErrMu.Unlock()
+	return p.streamErr
+}
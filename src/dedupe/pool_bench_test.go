@@ -0,0 +1,61 @@
+package dedupe
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// benchFileCount mirrors the ~10k-file directories this pool is meant to
+// speed up; it's kept smaller here so `go test -bench` stays fast.
+const benchFileCount = 2000
+
+// makeBenchGroups lays out benchFileCount files on disk across a handful of
+// duplicate groups and returns them keyed by size, as main's size-grouping
+// walk would.
+func makeBenchGroups(b *testing.B) map[int64][]string {
+	b.Helper()
+	dir := b.TempDir()
+
+	groups := map[int64][]string{}
+	const groupSize = 20
+	content := make([]byte, 8192)
+	for i := 0; i < benchFileCount/groupSize; i++ {
+		size := int64(1024 + i)
+		for j := 0; j < groupSize; j++ {
+			path := filepath.Join(dir, fmt.Sprintf("f-%d-%d", i, j))
+			if err := os.WriteFile(path, content[:size], 0o644); err != nil {
+				b.Fatal(err)
+			}
+			groups[size] = append(groups[size], path)
+		}
+	}
+	return groups
+}
+
+func BenchmarkRunSequential(b *testing.B) {
+	groups := makeBenchGroups(b)
+	p := NewPipeline(nil)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for size, paths := range groups {
+			if _, err := p.Run(size, paths); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkRunAllPooled(b *testing.B) {
+	groups := makeBenchGroups(b)
+	p := NewPipeline(nil)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := p.RunAll(groups, PoolOptions{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package dedupe
+
+// FindOriginal checks whether dup is a duplicate of any file in basePaths
+// (all of the given size) by running them together through the staged
+// pipeline. It returns the matching basedir path and true, or false if dup
+// has no match among basePaths.
+func (p *Pipeline) FindOriginal(size int64, basePaths []string, dup string) (string, bool, error) {
+	candidates := make([]string, 0, len(basePaths)+1)
+	candidates = append(candidates, basePaths...)
+	candidates = append(candidates, dup)
+
+	groups, err := p.Run(size, candidates)
+	if err != nil {
+		return "", false, err
+	}
+
+	for _, g := range groups {
+		var dupPresent bool
+		var original string
+		for _, path := range g.Paths {
+			if path == dup {
+				dupPresent = true
+				continue
+			}
+			if original == "" {
+				original = path
+			}
+		}
+		if dupPresent && original != "" {
+			return original, true, nil
+		}
+	}
+	return "", false, nil
+}
@@ -0,0 +1,52 @@
+package dedupe
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+// verifyBufSize is the chunk size used to stream both files through
+// bytes.Equal without loading either one fully into memory.
+const verifyBufSize = 1 << 20
+
+// VerifyEqual does a byte-for-byte comparison of a and b. It's the paranoid
+// last check run before acting on a hash match, in case two distinct files
+// collided in the selected algorithm.
+func VerifyEqual(a, b string) (bool, error) {
+	fa, err := os.Open(a)
+	if err != nil {
+		return false, err
+	}
+	defer fa.Close()
+
+	fb, err := os.Open(b)
+	if err != nil {
+		return false, err
+	}
+	defer fb.Close()
+
+	bufA := make([]byte, verifyBufSize)
+	bufB := make([]byte, verifyBufSize)
+	for {
+		nA, errA := io.ReadFull(fa, bufA)
+		nB, errB := io.ReadFull(fb, bufB)
+		if nA != nB || !bytes.Equal(bufA[:nA], bufB[:nB]) {
+			return false, nil
+		}
+		doneA := errA == io.EOF || errA == io.ErrUnexpectedEOF
+		doneB := errB == io.EOF || errB == io.ErrUnexpectedEOF
+		if doneA != doneB {
+			return false, nil
+		}
+		if doneA {
+			return true, nil
+		}
+		if errA != nil {
+			return false, errA
+		}
+		if errB != nil {
+			return false, errB
+		}
+	}
+}
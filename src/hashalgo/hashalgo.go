@@ -0,0 +1,48 @@
+// Package hashalgo selects the digest algorithm the dedupe pipeline hashes
+// files with. It's a separate package (rather than living on dedupe.Hasher
+// directly) so the --hash flag and its algorithm table stay in one place.
+package hashalgo
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+
+	"github.com/cespare/xxhash/v2"
+	"golang.org/x/crypto/blake2b"
+	"lukechampine.com/blake3"
+)
+
+// Default is used when no --hash flag is given: BLAKE2b is roughly 3x
+// faster than SHA256 on general-purpose CPUs with no hardware SHA
+// extension, at no meaningful cost to collision resistance for dedupe's
+// purposes.
+const Default = "blake2b"
+
+// factories maps a --hash flag value to a constructor for that algorithm's
+// hash.Hash.
+var factories = map[string]func() hash.Hash{
+	"sha256": sha256.New,
+	"sha1":   sha1.New,
+	"blake2b": func() hash.Hash {
+		h, _ := blake2b.New256(nil) // nil key never errors
+		return h
+	},
+	"blake3": func() hash.Hash { return blake3.New(32, nil) },
+	"xxh64":  func() hash.Hash { return xxhash.New() },
+}
+
+// New returns a hash.Hash constructor for the named algorithm.
+func New(name string) (func() hash.Hash, error) {
+	f, ok := factories[name]
+	if !ok {
+		return nil, fmt.Errorf("hashalgo: unknown algorithm %q", name)
+	}
+	return f, nil
+}
+
+// Names lists every supported --hash flag value, for usage text.
+func Names() []string {
+	return []string{"sha256", "sha1", "blake2b", "blake3", "xxh64"}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"os"
+	"sort"
+
+	"github.com/SAJAD-net/Dedupe/src/osutil"
+)
+
+// devIno identifies a file by the (device, inode) pair Unix platforms use
+// to tell two hardlinked directory entries from two files that merely have
+// identical contents.
+type devIno struct {
+	dev, ino uint64
+}
+
+// inodeIndex collapses hardlinked paths discovered during a walk down to a
+// single representative per (device, inode), while still counting how many
+// sibling links were seen and remain so --keep-hardlinks can refuse to
+// delete the last one. The sibling paths themselves are not stored.
+type inodeIndex struct {
+	// remaining counts how many on-disk links to a given inode are still
+	// known to exist; deletions decrement it.
+	remaining map[devIno]int
+}
+
+func newInodeIndex() *inodeIndex {
+	return &inodeIndex{remaining: make(map[devIno]int)}
+}
+
+// Observe records path during the scan. It returns false if path is an
+// extra hardlink to an inode already seen, in which case it must not be
+// added to the size-grouping map: it's the same on-disk file, not a
+// duplicate.
+func (idx *inodeIndex) Observe(path string, info os.FileInfo) bool {
+	dev, ino, ok := osutil.GetDevIno(info)
+	if !ok {
+		return true
+	}
+	key := devIno{dev, ino}
+	idx.remaining[key]++
+	return idx.remaining[key] == 1
+}
+
+// AllowDelete reports whether path may be deleted without severing the
+// last remaining link to its inode. When keepHardlinks is false, or the
+// platform has no inode support, every path is allowed.
+func (idx *inodeIndex) AllowDelete(path string, keepHardlinks bool) bool {
+	if !keepHardlinks {
+		return true
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		return true
+	}
+	dev, ino, ok := osutil.GetDevIno(info)
+	if !ok {
+		return true
+	}
+	key := devIno{dev, ino}
+	if idx.remaining[key] <= 1 {
+		return false
+	}
+	idx.remaining[key]--
+	return true
+}
+
+// sortByInodeLocality orders paths by (device, inode) so that hashing them
+// in sequence is kinder to a spinning disk's seek time than filesystem walk
+// order, which bears no relation to on-disk layout.
+func sortByInodeLocality(paths []string) {
+	type entry struct {
+		path     string
+		dev, ino uint64
+		ok       bool
+	}
+	entries := make([]entry, len(paths))
+	for i, p := range paths {
+		e := entry{path: p}
+		if info, err := os.Stat(p); err == nil {
+			e.dev, e.ino, e.ok = osutil.GetDevIno(info)
+		}
+		entries[i] = e
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if !a.ok || !b.ok {
+			return false
+		}
+		if a.dev != b.dev {
+			return a.dev < b.dev
+		}
+		return a.ino < b.ino
+	})
+
+	for i, e := range entries {
+		paths[i] = e.path
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/SAJAD-net/Dedupe/src/osutil"
+)
+
+func lstatOrFatal(t *testing.T, path string) os.FileInfo {
+	t.Helper()
+	info, err := os.Lstat(path)
+	if err != nil {
+		t.Fatalf("Lstat(%s): %v", path, err)
+	}
+	return info
+}
+
+func TestInodeIndexObserve(t *testing.T) {
+	if !osutil.OSHasInodes() {
+		t.Skip("platform has no inode support")
+	}
+
+	dir := t.TempDir()
+	original := filepath.Join(dir, "original")
+	hardlink := filepath.Join(dir, "hardlink")
+	distinct := filepath.Join(dir, "distinct")
+
+	if err := os.WriteFile(original, []byte("content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Link(original, hardlink); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(distinct, []byte("content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	idx := newInodeIndex()
+
+	if ok := idx.Observe(original, lstatOrFatal(t, original)); !ok {
+		t.Error("Observe(original) = false, want true (first sighting)")
+	}
+	if ok := idx.Observe(hardlink, lstatOrFatal(t, hardlink)); ok {
+		t.Error("Observe(hardlink) = true, want false (extra link to a seen inode)")
+	}
+	if ok := idx.Observe(distinct, lstatOrFatal(t, distinct)); !ok {
+		t.Error("Observe(distinct) = false, want true (different inode, same content)")
+	}
+}
+
+func TestInodeIndexAllowDelete(t *testing.T) {
+	if !osutil.OSHasInodes() {
+		t.Skip("platform has no inode support")
+	}
+
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a")
+	b := filepath.Join(dir, "b")
+	if err := os.WriteFile(a, []byte("content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Link(a, b); err != nil {
+		t.Fatal(err)
+	}
+
+	idx := newInodeIndex()
+	idx.Observe(a, lstatOrFatal(t, a))
+	idx.Observe(b, lstatOrFatal(t, b))
+
+	if !idx.AllowDelete(b, true) {
+		t.Fatal("AllowDelete(b, true) = false, want true: two links remain")
+	}
+	if idx.AllowDelete(a, true) {
+		t.Fatal("AllowDelete(a, true) = true, want false: a is the last remaining link")
+	}
+}
+
+func TestInodeIndexAllowDeleteIgnoresHardlinksWhenNotRequested(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a")
+	if err := os.WriteFile(a, []byte("content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	idx := newInodeIndex()
+	if !idx.AllowDelete(a, false) {
+		t.Fatal("AllowDelete(a, false) = false, want true: --keep-hardlinks not requested")
+	}
+}
+
+func TestSortByInodeLocality(t *testing.T) {
+	dir := t.TempDir()
+	var paths []string
+	for _, name := range []string{"c", "a", "b"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	before := append([]string(nil), paths...)
+	sortByInodeLocality(paths)
+
+	if len(paths) != len(before) {
+		t.Fatalf("sortByInodeLocality changed the number of paths: got %d, want %d", len(paths), len(before))
+	}
+	seen := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		seen[p] = true
+	}
+	for _, p := range before {
+		if !seen[p] {
+			t.Fatalf("sortByInodeLocality dropped %s", p)
+		}
+	}
+}
@@ -0,0 +1,5 @@
+// Package osutil abstracts the bits of file identity that differ across
+// platforms, namely whether a (device, inode) pair is available to tell two
+// directory entries pointing at the same on-disk file apart from two
+// entries that merely have identical contents.
+package osutil
@@ -0,0 +1,15 @@
+//go:build windows || plan9
+
+package osutil
+
+import "os"
+
+// OSHasInodes is false here: Windows and Plan 9 FileInfo don't expose a
+// stable (device, inode) pair through the standard library, so hardlink
+// detection is simply unavailable.
+func OSHasInodes() bool { return false }
+
+// GetDevIno always fails on these platforms; see OSHasInodes.
+func GetDevIno(fi os.FileInfo) (dev, ino uint64, ok bool) {
+	return 0, 0, false
+}
@@ -0,0 +1,22 @@
+//go:build unix
+
+package osutil
+
+import (
+	"os"
+	"syscall"
+)
+
+// OSHasInodes reports whether GetDevIno can identify files by (device,
+// inode) on this platform.
+func OSHasInodes() bool { return true }
+
+// GetDevIno returns the device and inode backing fi. ok is false if the
+// platform's FileInfo.Sys() doesn't carry that information.
+func GetDevIno(fi os.FileInfo) (dev, ino uint64, ok bool) {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+	return uint64(st.Dev), uint64(st.Ino), true
+}
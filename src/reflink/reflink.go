@@ -0,0 +1,7 @@
+package reflink
+
+import "errors"
+
+// ErrNotSupported is returned by Clone when the filesystem or platform has
+// no copy-on-write reflink support.
+var ErrNotSupported = errors.New("reflink: not supported")
@@ -0,0 +1,41 @@
+//go:build linux
+
+// Package reflink makes a copy-on-write clone of a file via the kernel
+// FICLONE ioctl, where the underlying filesystem supports it (btrfs, xfs,
+// overlayfs with the right backing store, ...).
+package reflink
+
+import (
+	"os"
+	"syscall"
+)
+
+// ficlone is the Linux FICLONE ioctl request number (include/uapi/linux/fs.h).
+const ficlone = 0x40049409
+
+// Clone reflinks src onto dst, which must not already exist. It returns
+// ErrNotSupported if the filesystem or kernel has no FICLONE support, so
+// callers can fall back to a hardlink or plain copy.
+func Clone(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, out.Fd(), ficlone, in.Fd())
+	if errno != 0 {
+		os.Remove(dst)
+		if errno == syscall.EOPNOTSUPP || errno == syscall.ENOTTY || errno == syscall.EXDEV {
+			return ErrNotSupported
+		}
+		return errno
+	}
+	return nil
+}
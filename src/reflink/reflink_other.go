@@ -0,0 +1,9 @@
+//go:build !linux
+
+package reflink
+
+// Clone always reports ErrNotSupported on platforms other than Linux, so
+// callers fall back to a hardlink.
+func Clone(src, dst string) error {
+	return ErrNotSupported
+}
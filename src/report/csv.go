@@ -0,0 +1,57 @@
+package report
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// csvReporter emits one row per duplicate path (not per group), since CSV
+// has no natural way to nest a group's duplicate list.
+type csvReporter struct {
+	w          *csv.Writer
+	headerOnce sync.Once
+}
+
+var csvHeader = []string{"hash", "algorithm", "size", "keeper", "keeper_mtime", "duplicate", "duplicate_mtime", "bytes_reclaimable"}
+
+func newCSVReporter(w io.Writer) *csvReporter {
+	return &csvReporter{w: csv.NewWriter(w)}
+}
+
+func (r *csvReporter) WriteRecord(rec Record) error {
+	var headerErr error
+	r.headerOnce.Do(func() { headerErr = r.w.Write(csvHeader) })
+	if headerErr != nil {
+		return headerErr
+	}
+
+	for i, dup := range rec.Duplicates {
+		mtime := time.Time{}
+		if i < len(rec.DuplicateMTimes) {
+			mtime = rec.DuplicateMTimes[i]
+		}
+		row := []string{
+			rec.Hash,
+			rec.Algorithm,
+			strconv.FormatInt(rec.Size, 10),
+			rec.Keeper,
+			rec.KeeperMTime.Format(time.RFC3339),
+			dup,
+			mtime.Format(time.RFC3339),
+			strconv.FormatInt(rec.BytesReclaimable, 10),
+		}
+		if err := r.w.Write(row); err != nil {
+			return err
+		}
+	}
+	r.w.Flush()
+	return r.w.Error()
+}
+
+func (r *csvReporter) Close() error {
+	r.w.Flush()
+	return r.w.Error()
+}
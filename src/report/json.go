@@ -0,0 +1,25 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// jsonReporter writes one JSON object per line (newline-delimited JSON) so
+// a reader can process records as they stream in without waiting for a
+// closing array bracket.
+type jsonReporter struct {
+	enc *json.Encoder
+}
+
+func newJSONReporter(w io.Writer) *jsonReporter {
+	return &jsonReporter{enc: json.NewEncoder(w)}
+}
+
+func (r *jsonReporter) WriteRecord(rec Record) error {
+	return r.enc.Encode(rec)
+}
+
+func (r *jsonReporter) Close() error {
+	return nil
+}
@@ -0,0 +1,32 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// netstringReporter encodes each record as JSON and wraps it in a netstring
+// ("<length>:<payload>,"), matching the streaming output style sgodup uses:
+// a reader never needs to scan for a delimiter, just read length-prefixed
+// frames.
+type netstringReporter struct {
+	w io.Writer
+}
+
+func newNetstringReporter(w io.Writer) *netstringReporter {
+	return &netstringReporter{w: w}
+}
+
+func (r *netstringReporter) WriteRecord(rec Record) error {
+	payload, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(r.w, "%d:%s,", len(payload), payload)
+	return err
+}
+
+func (r *netstringReporter) Close() error {
+	return nil
+}
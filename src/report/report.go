@@ -0,0 +1,49 @@
+// Package report turns duplicate groups into a structured, machine-readable
+// stream so other tooling can consume dedupe's findings without scraping
+// the human-readable printf output.
+package report
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// Record describes one confirmed duplicate group.
+type Record struct {
+	Hash             string
+	Algorithm        string
+	Size             int64
+	Keeper           string
+	KeeperMTime      time.Time
+	Duplicates       []string
+	DuplicateMTimes  []time.Time
+	BytesReclaimable int64
+}
+
+// Reporter streams Records out in some wire format as they're discovered,
+// rather than buffering the whole result set in memory.
+type Reporter interface {
+	// WriteRecord emits one duplicate group. It may be called many times
+	// and must flush enough that a reader can process records as they
+	// arrive.
+	WriteRecord(Record) error
+	// Close finalizes the stream (e.g. flushing buffered CSV rows) and releases
+	// any resources WriteRecord acquired.
+	Close() error
+}
+
+// New returns a Reporter for the named format, one of "json", "netstring",
+// or "csv".
+func New(format string, w io.Writer) (Reporter, error) {
+	switch format {
+	case "json":
+		return newJSONReporter(w), nil
+	case "netstring":
+		return newNetstringReporter(w), nil
+	case "csv":
+		return newCSVReporter(w), nil
+	default:
+		return nil, fmt.Errorf("report: unknown format %q", format)
+	}
+}
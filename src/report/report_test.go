@@ -0,0 +1,105 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testRecord() Record {
+	mtime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	return Record{
+		Hash:             "deadbeef",
+		Algorithm:        "blake2b",
+		Size:             1024,
+		Keeper:           "/data/a",
+		KeeperMTime:      mtime,
+		Duplicates:       []string{"/data/b", "/data/c"},
+		DuplicateMTimes:  []time.Time{mtime, mtime},
+		BytesReclaimable: 2048,
+	}
+}
+
+func TestReporters(t *testing.T) {
+	rec := testRecord()
+
+	tests := []struct {
+		format string
+		check  func(t *testing.T, out string)
+	}{
+		{
+			format: "json",
+			check: func(t *testing.T, out string) {
+				var got Record
+				if err := json.Unmarshal([]byte(strings.TrimSpace(out)), &got); err != nil {
+					t.Fatalf("json.Unmarshal: %v", err)
+				}
+				if got.Hash != rec.Hash || got.Algorithm != rec.Algorithm {
+					t.Fatalf("got %+v, want hash/algorithm %s/%s", got, rec.Hash, rec.Algorithm)
+				}
+			},
+		},
+		{
+			format: "netstring",
+			check: func(t *testing.T, out string) {
+				idx := strings.IndexByte(out, ':')
+				if idx < 0 || !strings.HasSuffix(out, ",") {
+					t.Fatalf("malformed netstring: %q", out)
+				}
+				payload := out[idx+1 : len(out)-1]
+				var got Record
+				if err := json.Unmarshal([]byte(payload), &got); err != nil {
+					t.Fatalf("json.Unmarshal: %v", err)
+				}
+				if got.Hash != rec.Hash || got.Algorithm != rec.Algorithm {
+					t.Fatalf("got %+v, want hash/algorithm %s/%s", got, rec.Hash, rec.Algorithm)
+				}
+			},
+		},
+		{
+			format: "csv",
+			check: func(t *testing.T, out string) {
+				rows, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+				if err != nil {
+					t.Fatalf("csv.ReadAll: %v", err)
+				}
+				// header + one row per duplicate
+				if len(rows) != 1+len(rec.Duplicates) {
+					t.Fatalf("got %d rows, want %d", len(rows), 1+len(rec.Duplicates))
+				}
+				if rows[0][0] != "hash" || rows[0][1] != "algorithm" {
+					t.Fatalf("unexpected header: %v", rows[0])
+				}
+				if rows[1][0] != rec.Hash || rows[1][1] != rec.Algorithm {
+					t.Fatalf("unexpected row: %v", rows[1])
+				}
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			r, err := New(tc.format, &buf)
+			if err != nil {
+				t.Fatalf("New(%q): %v", tc.format, err)
+			}
+			if err := r.WriteRecord(rec); err != nil {
+				t.Fatalf("WriteRecord: %v", err)
+			}
+			if err := r.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+			tc.check(t, buf.String())
+		})
+	}
+}
+
+func TestNewUnknownFormat(t *testing.T) {
+	if _, err := New("xml", &bytes.Buffer{}); err == nil {
+		t.Fatal("New(\"xml\", ...): expected error, got nil")
+	}
+}
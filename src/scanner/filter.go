@@ -0,0 +1,44 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// matches reports whether info passes every configured filter.
+func matches(path string, info os.FileInfo, opts Options) bool {
+	if len(opts.Include) > 0 && !matchAny(opts.Include, path) {
+		return false
+	}
+	if matchAny(opts.Exclude, path) {
+		return false
+	}
+
+	size := info.Size()
+	if size < opts.MinSize {
+		return false
+	}
+	if opts.MaxSize > 0 && size > opts.MaxSize {
+		return false
+	}
+
+	mtime := info.ModTime()
+	if !opts.NewerThan.IsZero() && mtime.Before(opts.NewerThan) {
+		return false
+	}
+	if !opts.OlderThan.IsZero() && mtime.After(opts.OlderThan) {
+		return false
+	}
+
+	return true
+}
+
+func matchAny(globs []string, path string) bool {
+	base := filepath.Base(path)
+	for _, g := range globs {
+		if ok, err := filepath.Match(g, base); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
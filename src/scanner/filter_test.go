@@ -0,0 +1,117 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type fakeFileInfo struct {
+	name  string
+	size  int64
+	mtime time.Time
+}
+
+func (f fakeFileInfo) Name() string       { return f.name }
+func (f fakeFileInfo) Size() int64        { return f.size }
+func (f fakeFileInfo) Mode() os.FileMode  { return 0o644 }
+func (f fakeFileInfo) ModTime() time.Time { return f.mtime }
+func (f fakeFileInfo) IsDir() bool        { return false }
+func (f fakeFileInfo) Sys() any           { return nil }
+
+func TestMatches(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		path string
+		info os.FileInfo
+		opts Options
+		want bool
+	}{
+		{
+			name: "no filters",
+			path: "/data/a.txt",
+			info: fakeFileInfo{name: "a.txt", size: 100, mtime: base},
+			opts: Options{},
+			want: true,
+		},
+		{
+			name: "include matches",
+			path: "/data/a.txt",
+			info: fakeFileInfo{name: "a.txt", size: 100, mtime: base},
+			opts: Options{Include: []string{"*.txt"}},
+			want: true,
+		},
+		{
+			name: "include does not match",
+			path: "/data/a.log",
+			info: fakeFileInfo{name: "a.log", size: 100, mtime: base},
+			opts: Options{Include: []string{"*.txt"}},
+			want: false,
+		},
+		{
+			name: "exclude overrides",
+			path: "/data/a.txt",
+			info: fakeFileInfo{name: "a.txt", size: 100, mtime: base},
+			opts: Options{Exclude: []string{"*.txt"}},
+			want: false,
+		},
+		{
+			name: "below minsize",
+			path: "/data/a.txt",
+			info: fakeFileInfo{name: "a.txt", size: 10, mtime: base},
+			opts: Options{MinSize: 100},
+			want: false,
+		},
+		{
+			name: "above maxsize",
+			path: "/data/a.txt",
+			info: fakeFileInfo{name: "a.txt", size: 1000, mtime: base},
+			opts: Options{MaxSize: 100},
+			want: false,
+		},
+		{
+			name: "maxsize zero means unlimited",
+			path: "/data/a.txt",
+			info: fakeFileInfo{name: "a.txt", size: 1 << 30, mtime: base},
+			opts: Options{MaxSize: 0},
+			want: true,
+		},
+		{
+			name: "older than newer-than cutoff",
+			path: "/data/a.txt",
+			info: fakeFileInfo{name: "a.txt", size: 100, mtime: base.Add(-time.Hour)},
+			opts: Options{NewerThan: base},
+			want: false,
+		},
+		{
+			name: "newer than older-than cutoff",
+			path: "/data/a.txt",
+			info: fakeFileInfo{name: "a.txt", size: 100, mtime: base.Add(time.Hour)},
+			opts: Options{OlderThan: base},
+			want: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := matches(tc.path, tc.info, tc.opts); got != tc.want {
+				t.Errorf("matches(%q, %+v) = %v, want %v", tc.path, tc.opts, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMatchAny(t *testing.T) {
+	if !matchAny([]string{"*.txt", "*.log"}, filepath.Join("dir", "a.log")) {
+		t.Error("expected a.log to match *.log")
+	}
+	if matchAny([]string{"*.txt"}, filepath.Join("dir", "a.log")) {
+		t.Error("did not expect a.log to match *.txt")
+	}
+	if matchAny(nil, "a.log") {
+		t.Error("empty glob list should never match")
+	}
+}
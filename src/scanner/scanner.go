@@ -0,0 +1,60 @@
+// Package scanner produces the candidate file list dedupe hashes, either by
+// walking a set of root directories or by reading a pre-filtered path list
+// from stdin, applying the same include/exclude/size/time filters either
+// way.
+package scanner
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+)
+
+// FileEntry is one candidate file discovered by Scan.
+type FileEntry struct {
+	Path string
+	Info os.FileInfo
+}
+
+// Options configures a Scan. Roots is ignored when FromStdin is set.
+type Options struct {
+	Roots []string
+
+	FromStdin bool
+	// Stdin is read for the path list when FromStdin is set. Defaults to
+	// os.Stdin when nil.
+	Stdin io.Reader
+	// NullDelimited splits the Stdin path list on NUL bytes (as produced
+	// by `find -print0`) instead of newlines.
+	NullDelimited bool
+
+	// Include, if non-empty, keeps only files whose base name matches at
+	// least one of these filepath.Match globs. Exclude drops any file
+	// whose base name matches one of its globs, applied after Include.
+	Include []string
+	Exclude []string
+
+	MinSize   int64
+	MaxSize   int64 // 0 means unlimited
+	NewerThan time.Time
+	OlderThan time.Time
+}
+
+// Scan walks Roots (or reads Stdin, per Options) and streams every file
+// that passes the configured filters. The channel is closed once the scan
+// finishes or ctx is canceled, whichever comes first.
+func Scan(ctx context.Context, opts Options) <-chan FileEntry {
+	out := make(chan FileEntry)
+
+	go func() {
+		defer close(out)
+		if opts.FromStdin {
+			scanStdin(ctx, opts, out)
+			return
+		}
+		scanRoots(ctx, opts, out)
+	}()
+
+	return out
+}
@@ -0,0 +1,64 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"log"
+	"os"
+)
+
+// scanStdin reads a newline- or NUL-delimited path list from opts.Stdin (or
+// os.Stdin if unset) and sends every entry that passes the filters to out.
+// This lets `find`, `fd`, or a backup manifest feed a pre-filtered
+// candidate set straight into dedupe.
+func scanStdin(ctx context.Context, opts Options, out chan<- FileEntry) {
+	r := opts.Stdin
+	if r == nil {
+		r = os.Stdin
+	}
+
+	sep := byte('\n')
+	if opts.NullDelimited {
+		sep = 0
+	}
+
+	reader := bufio.NewReader(r)
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		line, err := reader.ReadString(sep)
+		path := trimSeparator(line, sep)
+		if path != "" {
+			info, statErr := os.Lstat(path)
+			switch {
+			case statErr != nil:
+				log.Printf("Error accessing %s: %v", path, statErr)
+			case info.IsDir() || info.Mode()&os.ModeSymlink != 0:
+				// skip
+			case matches(path, info, opts):
+				select {
+				case out <- FileEntry{Path: path, Info: info}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+
+		if err != nil {
+			if err != io.EOF {
+				log.Printf("Error reading path list: %v", err)
+			}
+			return
+		}
+	}
+}
+
+func trimSeparator(s string, sep byte) string {
+	if len(s) > 0 && s[len(s)-1] == sep {
+		s = s[:len(s)-1]
+	}
+	return s
+}
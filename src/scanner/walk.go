@@ -0,0 +1,45 @@
+package scanner
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// scanRoots walks each of opts.Roots, sending every regular file that
+// passes the filters to out. It mirrors the walk dedupe's main used to do
+// inline: skip directories and symlinks, log (don't fail) on access
+// errors.
+func scanRoots(ctx context.Context, opts Options, out chan<- FileEntry) {
+	for _, root := range opts.Roots {
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if err != nil {
+				log.Printf("Error accessing %s: %v", path, err)
+				return nil
+			}
+			if info.IsDir() || info.Mode()&os.ModeSymlink != 0 {
+				return nil
+			}
+			if !matches(path, info, opts) {
+				return nil
+			}
+
+			select {
+			case out <- FileEntry{Path: path, Info: info}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			return nil
+		})
+		if err != nil && err != ctx.Err() {
+			log.Printf("Error walking %s: %v", root, err)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package main
+
+import "strings"
+
+// stringList implements flag.Value for a repeatable flag, e.g.
+// --include=a --include=b.
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}